@@ -0,0 +1,177 @@
+// Package action repairs or removes files that ProcessFolder/ProcessManifest
+// flagged as corrupted or invalid, so a content-addressed store can be
+// healed automatically instead of only being reported on.
+package action
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konidev20/refcheck/internal/hash"
+)
+
+// Action repairs or removes a single flagged file.
+type Action interface {
+	// Describe reports what Apply would do to filePath, without doing it.
+	Describe(filePath string) string
+	// Apply performs the action against filePath and reports what it did.
+	Apply(filePath string) (string, error)
+}
+
+// Quarantine moves a flagged file into Dir, preserving its base name.
+type Quarantine struct {
+	Dir string
+}
+
+func (q Quarantine) Describe(filePath string) string {
+	return fmt.Sprintf("quarantine to %s", filepath.Join(q.Dir, filepath.Base(filePath)))
+}
+
+func (q Quarantine) Apply(filePath string) (string, error) {
+	if err := os.MkdirAll(q.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("quarantine: creating %s: %w", q.Dir, err)
+	}
+	dest := filepath.Join(q.Dir, filepath.Base(filePath))
+	if err := os.Rename(filePath, dest); err != nil {
+		return "", fmt.Errorf("quarantine: moving %s: %w", filePath, err)
+	}
+	return fmt.Sprintf("quarantined to %s", dest), nil
+}
+
+// Delete removes a flagged file outright.
+type Delete struct{}
+
+func (Delete) Describe(filePath string) string {
+	return "delete"
+}
+
+func (Delete) Apply(filePath string) (string, error) {
+	if err := os.Remove(filePath); err != nil {
+		return "", fmt.Errorf("delete: %w", err)
+	}
+	return "deleted", nil
+}
+
+// Rename appends Suffix to a flagged file's name, leaving it in place but out
+// of the way of anything expecting the original name.
+type Rename struct {
+	Suffix string
+}
+
+func (r Rename) Describe(filePath string) string {
+	return fmt.Sprintf("rename to %s", filepath.Base(filePath)+r.Suffix)
+}
+
+func (r Rename) Apply(filePath string) (string, error) {
+	dest := filePath + r.Suffix
+	if err := os.Rename(filePath, dest); err != nil {
+		return "", fmt.Errorf("rename: %w", err)
+	}
+	return fmt.Sprintf("renamed to %s", filepath.Base(dest)), nil
+}
+
+// FetchFrom re-downloads a flagged file's content from a mirror and
+// overwrites it in place, letting a corrupted content-addressed blob store
+// self-heal. URLTemplate's "{hash}" placeholder is replaced with filePath's
+// basename, which is assumed to be the expected digest; the download is
+// re-verified against that digest before it replaces the original.
+type FetchFrom struct {
+	URLTemplate string
+}
+
+func (f FetchFrom) url(filePath string) string {
+	return strings.ReplaceAll(f.URLTemplate, "{hash}", filepath.Base(filePath))
+}
+
+func (f FetchFrom) Describe(filePath string) string {
+	return fmt.Sprintf("re-fetch from %s", f.url(filePath))
+}
+
+func (f FetchFrom) Apply(filePath string) (string, error) {
+	expectedHash := filepath.Base(filePath)
+	algo, ok := hash.Detect(expectedHash)
+	if !ok {
+		return "", fmt.Errorf("fetch: %s is not a recognized digest, cannot derive a URL", expectedHash)
+	}
+
+	url := f.url(filePath)
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch: %s returned %s", url, resp.Status)
+	}
+
+	tmp := filePath + ".fetching"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+
+	h, err := hash.New(algo)
+	if err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(out, h), resp.Body); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+	out.Close()
+
+	actualHash := hex.EncodeToString(h.Sum(nil))
+	if actualHash != expectedHash {
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetch: %s served content hashing to %s, not %s", url, actualHash, expectedHash)
+	}
+
+	if err := os.Rename(tmp, filePath); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+	return fmt.Sprintf("re-fetched from %s", url), nil
+}
+
+// Parse parses a --on-corrupt/--on-invalid flag value of the form
+// "name" or "name:arg" into an Action. Recognized names are "quarantine"
+// (arg is the destination directory), "delete", "rename" (arg is the
+// suffix, default ".bad"), and "fetch" (arg is a URL template containing
+// "{hash}"). An empty spec returns a nil Action.
+func Parse(spec string) (Action, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	name, arg, _ := strings.Cut(spec, ":")
+	switch name {
+	case "quarantine":
+		if arg == "" {
+			return nil, fmt.Errorf("quarantine requires a directory, e.g. quarantine:/var/bad")
+		}
+		return Quarantine{Dir: arg}, nil
+	case "delete":
+		return Delete{}, nil
+	case "rename":
+		if arg == "" {
+			arg = ".bad"
+		}
+		return Rename{Suffix: arg}, nil
+	case "fetch":
+		if arg == "" {
+			return nil, fmt.Errorf("fetch requires a URL template, e.g. fetch:https://mirror.example.com/{hash}")
+		}
+		return FetchFrom{URLTemplate: arg}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", name)
+	}
+}
@@ -0,0 +1,165 @@
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParse(t *testing.T) {
+	if a, err := Parse(""); err != nil || a != nil {
+		t.Fatalf("Parse(\"\") = (%v, %v), want (nil, nil)", a, err)
+	}
+
+	if _, err := Parse("quarantine"); err == nil {
+		t.Fatal("expected error for quarantine without a directory")
+	}
+	if a, err := Parse("quarantine:/var/bad"); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	} else if q, ok := a.(Quarantine); !ok || q.Dir != "/var/bad" {
+		t.Fatalf("Parse(\"quarantine:/var/bad\") = %#v, want Quarantine{Dir: \"/var/bad\"}", a)
+	}
+
+	if a, err := Parse("delete"); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	} else if _, ok := a.(Delete); !ok {
+		t.Fatalf("Parse(\"delete\") = %#v, want Delete{}", a)
+	}
+
+	if a, err := Parse("rename"); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	} else if r, ok := a.(Rename); !ok || r.Suffix != ".bad" {
+		t.Fatalf("Parse(\"rename\") = %#v, want Rename{Suffix: \".bad\"}", a)
+	}
+
+	if _, err := Parse("fetch"); err == nil {
+		t.Fatal("expected error for fetch without a URL template")
+	}
+
+	if _, err := Parse("bogus"); err == nil {
+		t.Fatal("expected error for unknown action name")
+	}
+}
+
+func TestQuarantineApply(t *testing.T) {
+	dir := t.TempDir()
+	quarantineDir := filepath.Join(dir, "bad")
+
+	src := filepath.Join(dir, "deadbeef")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	q := Quarantine{Dir: quarantineDir}
+	if _, err := q.Apply(src); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatal("expected source file to be gone after quarantine")
+	}
+	if _, err := os.Stat(filepath.Join(quarantineDir, "deadbeef")); err != nil {
+		t.Fatalf("expected file in quarantine dir: %v", err)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "deadbeef")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	records := Run(Delete{}, nil, []string{src}, nil, false)
+	if len(records) != 1 || records[0].Applied {
+		t.Fatalf("Run (dry run) = %#v, want one unapplied record", records)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("dry run must not delete the file: %v", err)
+	}
+}
+
+func TestFetchFromApply(t *testing.T) {
+	const goodContent = "the real content"
+	goodHash := sha256Hex(goodContent)
+
+	// The mirror always serves goodContent, regardless of which hash was
+	// requested, so a request for a mismatched hash exercises the
+	// post-download verification rather than a 404.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, goodContent)
+	}))
+	defer srv.Close()
+
+	t.Run("success", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, goodHash)
+		if err := os.WriteFile(filePath, []byte("stale corrupted content"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		f := FetchFrom{URLTemplate: srv.URL + "/{hash}"}
+		if _, err := f.Apply(filePath); err != nil {
+			t.Fatalf("Apply returned error: %v", err)
+		}
+
+		got, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if string(got) != goodContent {
+			t.Fatalf("Apply left content %q, want %q", got, goodContent)
+		}
+	})
+
+	t.Run("hash mismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		mismatchHash := sha256Hex("this is not what the server returns")
+		filePath := filepath.Join(dir, mismatchHash)
+		if err := os.WriteFile(filePath, []byte("original content"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		f := FetchFrom{URLTemplate: srv.URL + "/{hash}"}
+		if _, err := f.Apply(filePath); err == nil {
+			t.Fatal("expected an error when the fetched content does not match the expected hash")
+		}
+
+		got, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if string(got) != "original content" {
+			t.Fatalf("Apply must not overwrite the original file on a hash mismatch, got %q", got)
+		}
+		if _, err := os.Stat(filePath + ".fetching"); !os.IsNotExist(err) {
+			t.Fatal("expected the temporary download to be cleaned up on failure")
+		}
+	})
+}
+
+func TestRunFailedApplyIsNotMarkedApplied(t *testing.T) {
+	// A path that doesn't exist makes Delete.Apply fail.
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	records := Run(Delete{}, nil, []string{missing}, nil, true)
+	if len(records) != 1 {
+		t.Fatalf("Run = %#v, want one record", records)
+	}
+	if records[0].Applied {
+		t.Fatalf("Run = %#v, want Applied=false for a failed action", records[0])
+	}
+	if records[0].Error == "" {
+		t.Fatalf("Run = %#v, want a non-empty Error", records[0])
+	}
+}
@@ -0,0 +1,46 @@
+package action
+
+// Record reports the outcome of running an Action against a single flagged
+// file. Applied is true only once the action has actually run and
+// succeeded; a dry run or a failed Apply both leave it false.
+type Record struct {
+	FilePath string `json:"file_path"`
+	Applied  bool   `json:"applied"`
+	Detail   string `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Run applies onCorrupt to every path in corrupted and onInvalid to every
+// path in invalid, returning a Record per file. Either Action may be nil, in
+// which case the corresponding paths are skipped entirely. With apply
+// false, nothing is mutated and each Record's Detail describes what would
+// have happened.
+func Run(onCorrupt, onInvalid Action, corrupted, invalid []string, apply bool) []Record {
+	records := runOne(onCorrupt, corrupted, apply)
+	records = append(records, runOne(onInvalid, invalid, apply)...)
+	return records
+}
+
+func runOne(a Action, paths []string, apply bool) []Record {
+	if a == nil {
+		return nil
+	}
+
+	records := make([]Record, 0, len(paths))
+	for _, path := range paths {
+		if !apply {
+			records = append(records, Record{FilePath: path, Detail: a.Describe(path)})
+			continue
+		}
+
+		detail, err := a.Apply(path)
+		rec := Record{FilePath: path, Applied: err == nil}
+		if err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Detail = detail
+		}
+		records = append(records, rec)
+	}
+	return records
+}
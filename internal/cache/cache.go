@@ -0,0 +1,116 @@
+// Package cache memoizes file verification results so repeated refcheck runs
+// over large, mostly-unchanged trees (e.g. a nightly pass over a 10TB restic
+// repo) can skip re-hashing files whose path, size, and modification time
+// haven't changed since they were last verified.
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/konidev20/refcheck/internal/hash"
+)
+
+// Entry is a single memoized verification result.
+type Entry struct {
+	Path           string    `json:"path"`
+	Size           int64     `json:"size"`
+	ModTime        time.Time `json:"mod_time"`
+	Algo           hash.Algo `json:"algo"`
+	Hash           string    `json:"hash"`
+	LastVerifiedAt time.Time `json:"last_verified_at"`
+}
+
+// Store is an on-disk, JSON-backed cache of Entry values, safe for
+// concurrent use by ProcessFolder's workers.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]Entry
+	dirty   bool
+}
+
+// Open loads the cache at path, creating an empty one if it doesn't exist
+// yet. Call Flush to persist changes back to path.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func entryKey(path string, algo hash.Algo) string {
+	return path + "\x00" + string(algo)
+}
+
+// Lookup returns the cached digest for path if an entry exists whose size
+// and modTime match and which is no older than maxAge (a zero maxAge means
+// no expiry), along with whether such an entry was found.
+func (s *Store) Lookup(path string, size int64, modTime time.Time, algo hash.Algo, maxAge time.Duration) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[entryKey(path, algo)]
+	if !ok || e.Size != size || !e.ModTime.Equal(modTime) {
+		return "", false
+	}
+	if maxAge > 0 && time.Since(e.LastVerifiedAt) > maxAge {
+		return "", false
+	}
+	return e.Hash, true
+}
+
+// Put records a freshly verified digest for path.
+func (s *Store) Put(path string, size int64, modTime time.Time, algo hash.Algo, digest string, verifiedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entryKey(path, algo)] = Entry{
+		Path:           path,
+		Size:           size,
+		ModTime:        modTime,
+		Algo:           algo,
+		Hash:           digest,
+		LastVerifiedAt: verifiedAt,
+	}
+	s.dirty = true
+}
+
+// Flush writes pending changes to disk. It is a no-op if nothing changed
+// since the cache was opened or last flushed.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
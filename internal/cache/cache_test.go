@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konidev20/refcheck/internal/hash"
+)
+
+func TestStoreLookupPut(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "cache.json")
+	s, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, ok := s.Lookup("/a", 10, modTime, hash.SHA256, 0); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	s.Put("/a", 10, modTime, hash.SHA256, "deadbeef", time.Now())
+
+	if digest, ok := s.Lookup("/a", 10, modTime, hash.SHA256, 0); !ok || digest != "deadbeef" {
+		t.Fatalf("Lookup = (%q, %v), want (\"deadbeef\", true)", digest, ok)
+	}
+
+	if _, ok := s.Lookup("/a", 11, modTime, hash.SHA256, 0); ok {
+		t.Fatal("expected miss when size changed")
+	}
+
+	if _, ok := s.Lookup("/a", 10, modTime.Add(time.Second), hash.SHA256, 0); ok {
+		t.Fatal("expected miss when mtime changed")
+	}
+}
+
+func TestStorePersistence(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "cache.json")
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	s.Put("/a", 10, modTime, hash.SHA256, "deadbeef", time.Now())
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	reopened, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("re-Open returned error: %v", err)
+	}
+	if digest, ok := reopened.Lookup("/a", 10, modTime, hash.SHA256, 0); !ok || digest != "deadbeef" {
+		t.Fatalf("Lookup after reopen = (%q, %v), want (\"deadbeef\", true)", digest, ok)
+	}
+}
+
+func TestStoreMaxAge(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "cache.json")
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	s.Put("/a", 10, modTime, hash.SHA256, "deadbeef", time.Now().Add(-48*time.Hour))
+
+	if _, ok := s.Lookup("/a", 10, modTime, hash.SHA256, 24*time.Hour); ok {
+		t.Fatal("expected miss when entry older than maxAge")
+	}
+	if _, ok := s.Lookup("/a", 10, modTime, hash.SHA256, 72*time.Hour); !ok {
+		t.Fatal("expected hit when entry within maxAge")
+	}
+}
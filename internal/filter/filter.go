@@ -0,0 +1,194 @@
+// Package filter implements gitignore-style path filtering: glob segments,
+// "**" recursive matching, "!" negation, and directory-only patterns. It
+// replaces the single combined regular expression refcheck used to build
+// from --exclude flags and templates, which couldn't express "any depth" or
+// "this segment only" without callers hand-rolling regex themselves.
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the name of the per-directory ignore file Filter loads
+// while walking a tree, analogous to git's .gitignore.
+const IgnoreFileName = ".refcheckignore"
+
+type pattern struct {
+	negated  bool
+	dirOnly  bool
+	anchored bool
+	segments []string
+
+	// base is the root-relative, slash-separated directory the pattern was
+	// defined in (empty for root-level --exclude/template patterns). A
+	// .refcheckignore pattern only matches paths under its own base, exactly
+	// like gitignore scopes a nested .gitignore to its directory and below.
+	base string
+}
+
+// parsePattern parses a single gitignore-style line into a pattern. Callers
+// must strip comments and blank lines before calling it.
+func parsePattern(raw string) pattern {
+	var p pattern
+	line := raw
+
+	if strings.HasPrefix(line, "!") {
+		p.negated = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+		p.anchored = true
+	}
+
+	p.segments = strings.Split(line, "/")
+	if len(p.segments) > 1 {
+		// A pattern containing a non-trailing slash is anchored to the
+		// directory it's defined relative to, per gitignore semantics.
+		p.anchored = true
+	}
+
+	return p
+}
+
+// matches reports whether pattern matches a slash-separated, root-relative
+// path whose final component is a directory iff isDir.
+func (p pattern) matches(path string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	sub := path
+	if p.base != "" {
+		switch {
+		case path == p.base:
+			sub = ""
+		case strings.HasPrefix(path, p.base+"/"):
+			sub = path[len(p.base)+1:]
+		default:
+			return false
+		}
+	}
+
+	var segments []string
+	if sub != "" {
+		segments = strings.Split(sub, "/")
+	}
+
+	if p.anchored {
+		return matchSegments(p.segments, segments)
+	}
+
+	for start := 0; start <= len(segments); start++ {
+		if matchSegments(p.segments, segments[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments walks pattern segments against path segments, treating "**"
+// as zero or more path components and any other segment as a single-component
+// glob (via filepath.Match).
+func matchSegments(pat, path []string) bool {
+	if len(pat) == 0 {
+		return len(path) == 0
+	}
+
+	if pat[0] == "**" {
+		if matchSegments(pat[1:], path) {
+			return true
+		}
+		if len(path) == 0 {
+			return false
+		}
+		return matchSegments(pat, path[1:])
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pat[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pat[1:], path[1:])
+}
+
+// Filter decides whether paths under root should be included, by evaluating
+// gitignore-style patterns loaded from --exclude flags, templates, and
+// .refcheckignore files discovered while walking.
+type Filter struct {
+	root     string
+	patterns []pattern
+}
+
+// New builds a Filter rooted at root, seeded with patterns (one per line,
+// as they'd appear in a .refcheckignore file). Blank lines and "#" comments
+// are ignored. These root-level patterns apply anywhere under root.
+func New(root string, patterns []string) *Filter {
+	f := &Filter{root: root}
+	f.addLines(patterns, "")
+	return f
+}
+
+func (f *Filter) addLines(lines []string, base string) {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		p := parsePattern(trimmed)
+		p.base = base
+		f.patterns = append(f.patterns, p)
+	}
+}
+
+// LoadIgnoreFile reads additional patterns from the .refcheckignore file at
+// path and appends them, so later patterns (e.g. from a deeper directory)
+// can override earlier ones, matching gitignore's last-match-wins semantics.
+// The loaded patterns are scoped to path's directory and below, exactly
+// like a nested .gitignore never affects its siblings or ancestors.
+func (f *Filter) LoadIgnoreFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	base, err := filepath.Rel(f.root, filepath.Dir(path))
+	if err != nil {
+		base = filepath.ToSlash(filepath.Dir(path))
+	} else if base == "." {
+		base = ""
+	} else {
+		base = filepath.ToSlash(base)
+	}
+
+	f.addLines(strings.Split(string(data), "\n"), base)
+	return nil
+}
+
+// Includes reports whether path, an absolute or root-relative path, should be
+// included. The last pattern that matches decides the outcome; if no pattern
+// matches, the path is included.
+func (f *Filter) Includes(path string, isDir bool) bool {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil {
+		rel = path
+	}
+	rel = filepath.ToSlash(rel)
+
+	included := true
+	for _, p := range f.patterns {
+		if p.matches(rel, isDir) {
+			included = p.negated
+		}
+	}
+	return included
+}
@@ -0,0 +1,63 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilterIncludes(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"plain literal", []string{"config"}, "/root/config", false, false},
+		{"nested literal unanchored", []string{"config"}, "/root/sub/config", false, false},
+		{"anchored only matches root", []string{"/config"}, "/root/sub/config", false, true},
+		{"single star within component", []string{"*.tmp"}, "/root/a.tmp", false, false},
+		{"unanchored star matches in subdirectory", []string{"*.tmp"}, "/root/sub/a.tmp", false, false},
+		{"double star crosses slash", []string{"**/a.tmp"}, "/root/sub/deep/a.tmp", false, false},
+		{"dir only skips files", []string{"build/"}, "/root/build", false, true},
+		{"dir only matches dirs", []string{"build/"}, "/root/build", true, false},
+		{"negation re-includes", []string{"*.tmp", "!keep.tmp"}, "/root/keep.tmp", false, true},
+		{"no match is included", []string{"config"}, "/root/other", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New("/root", tt.patterns)
+			if got := f.Includes(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Includes(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadIgnoreFileIsScoped verifies that patterns from a .refcheckignore
+// found in a subdirectory only apply within that subdirectory, not tree-wide
+// like --exclude flags and templates do.
+func TestLoadIgnoreFileIsScoped(t *testing.T) {
+	root := t.TempDir()
+	subIgnore := filepath.Join(root, "sub", IgnoreFileName)
+	if err := os.MkdirAll(filepath.Dir(subIgnore), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(subIgnore, []byte("build/\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	f := New(root, nil)
+	if err := f.LoadIgnoreFile(subIgnore); err != nil {
+		t.Fatalf("LoadIgnoreFile returned error: %v", err)
+	}
+
+	if !f.Includes(filepath.Join(root, "build"), true) {
+		t.Error("expected top-level build/ to remain included")
+	}
+	if f.Includes(filepath.Join(root, "sub", "build"), true) {
+		t.Error("expected sub/build/ to be excluded by sub/.refcheckignore")
+	}
+}
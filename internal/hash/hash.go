@@ -0,0 +1,104 @@
+// Package hash abstracts over the different digest algorithms refcheck can
+// verify filenames against. Content-addressed stores do not all agree on one
+// hash function (git objects use SHA-1, restic keys and most release tarballs
+// use SHA-256, some use SHA-512, and newer tools favor BLAKE2b or BLAKE3), so
+// callers work against the Algo type instead of hardcoding crypto/sha256.
+package hash
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"regexp"
+
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+// Algo identifies a supported digest algorithm.
+type Algo string
+
+const (
+	SHA1    Algo = "sha1"
+	SHA256  Algo = "sha256"
+	SHA512  Algo = "sha512"
+	BLAKE2b Algo = "blake2b"
+	BLAKE3  Algo = "blake3"
+)
+
+// All lists every supported algorithm. Detect does not consult it; it picks
+// an algorithm from the digest's length alone.
+var All = []Algo{SHA256, SHA1, SHA512, BLAKE2b, BLAKE3}
+
+var hexPattern = regexp.MustCompile(`^[a-f0-9]+$`)
+
+// HexLen returns the number of hex characters a digest produced by algo has.
+func HexLen(algo Algo) int {
+	switch algo {
+	case SHA1:
+		return 40
+	case SHA256, BLAKE2b, BLAKE3:
+		return 64
+	case SHA512:
+		return 128
+	default:
+		return 0
+	}
+}
+
+// New returns a fresh hash.Hash for algo, or an error if algo is unsupported.
+func New(algo Algo) (hash.Hash, error) {
+	switch algo {
+	case SHA1:
+		return sha1.New(), nil
+	case SHA256:
+		return sha256.New(), nil
+	case SHA512:
+		return sha512.New(), nil
+	case BLAKE2b:
+		return blake2b.New256(nil)
+	case BLAKE3:
+		return blake3.New(32, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algo)
+	}
+}
+
+// Detect guesses the algorithm used to produce digest, a filename-sized hex
+// string, based on its length. SHA-256, BLAKE2b, and BLAKE3 all produce
+// 64-character digests, so ties are broken in favor of SHA-256, refcheck's
+// long-standing default; pass an explicit Algo via --algo to disambiguate.
+func Detect(digest string) (Algo, bool) {
+	if !hexPattern.MatchString(digest) {
+		return "", false
+	}
+	switch len(digest) {
+	case HexLen(SHA1):
+		return SHA1, true
+	case HexLen(SHA256):
+		return SHA256, true
+	case HexLen(SHA512):
+		return SHA512, true
+	default:
+		return "", false
+	}
+}
+
+// Valid reports whether digest is a well-formed hex digest for algo.
+func Valid(algo Algo, digest string) bool {
+	return len(digest) == HexLen(algo) && hexPattern.MatchString(digest)
+}
+
+// Parse converts a user-supplied --algo value into an Algo, rejecting
+// anything refcheck doesn't support.
+func Parse(s string) (Algo, error) {
+	algo := Algo(s)
+	for _, a := range All {
+		if a == algo {
+			return algo, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported hash algorithm %q", s)
+}
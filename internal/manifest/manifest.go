@@ -0,0 +1,85 @@
+// Package manifest loads external manifests (SHA256SUMS-style files or JSON
+// maps) that record a file's expected hash separately from its filename.
+// This lets refcheck verify trees where filenames are human-readable, such
+// as release tarballs or backup snapshots, instead of relying on
+// "filename == digest". Restic-style indexes are not parsed.
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Parser turns a manifest file's contents into a map of relative path to
+// expected hex digest. New manifest formats plug in by implementing it.
+type Parser interface {
+	Parse(r io.Reader) (map[string]string, error)
+}
+
+// SumsParser parses SHA256SUMS-style manifests: one "<hash>  <path>" entry
+// per line, as produced by sha256sum/sha1sum/shasum. A leading "*" before
+// the path (binary mode) is stripped.
+type SumsParser struct{}
+
+func (SumsParser) Parse(r io.Reader) (map[string]string, error) {
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "  ", 2)
+		if len(fields) != 2 {
+			fields = strings.SplitN(line, " ", 2)
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("manifest: malformed line %q", line)
+		}
+
+		path := strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")
+		entries[filepath.ToSlash(path)] = strings.ToLower(fields[0])
+	}
+	return entries, scanner.Err()
+}
+
+// JSONParser parses a manifest as a JSON object mapping relative path to
+// expected hex digest, e.g. {"bin/app": "abc123..."}.
+type JSONParser struct{}
+
+func (JSONParser) Parse(r io.Reader) (map[string]string, error) {
+	entries := make(map[string]string)
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	return entries, nil
+}
+
+// ParserForPath selects a Parser based on a manifest file's extension,
+// defaulting to the SHA256SUMS-style format.
+func ParserForPath(path string) Parser {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSONParser{}
+	default:
+		return SumsParser{}
+	}
+}
+
+// Load reads and parses the manifest at path into a map of relative path to
+// expected hex digest.
+func Load(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParserForPath(path).Parse(f)
+}
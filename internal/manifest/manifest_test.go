@@ -0,0 +1,54 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSumsParser(t *testing.T) {
+	input := strings.Join([]string{
+		"# comment",
+		"",
+		"abc123  bin/app",
+		"def456 *bin/lib.so",
+	}, "\n")
+
+	entries, err := SumsParser{}.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"bin/app":    "abc123",
+		"bin/lib.so": "def456",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for path, hash := range want {
+		if entries[path] != hash {
+			t.Errorf("entries[%q] = %q, want %q", path, entries[path], hash)
+		}
+	}
+}
+
+func TestJSONParser(t *testing.T) {
+	input := `{"bin/app": "abc123"}`
+
+	entries, err := JSONParser{}.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if entries["bin/app"] != "abc123" {
+		t.Errorf("entries[\"bin/app\"] = %q, want %q", entries["bin/app"], "abc123")
+	}
+}
+
+func TestParserForPath(t *testing.T) {
+	if _, ok := ParserForPath("sums.json").(JSONParser); !ok {
+		t.Error("expected ParserForPath(\"sums.json\") to return JSONParser")
+	}
+	if _, ok := ParserForPath("SHA256SUMS").(SumsParser); !ok {
+		t.Error("expected ParserForPath(\"SHA256SUMS\") to return SumsParser")
+	}
+}
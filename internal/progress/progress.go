@@ -0,0 +1,25 @@
+// Package progress defines the per-file events validator emits as it works,
+// so callers can render a live view (a progress bar, a streaming table, a
+// watch-mode log) instead of waiting for a final Result.
+package progress
+
+import "github.com/konidev20/refcheck/internal/hash"
+
+// Status classifies the outcome of verifying a single file.
+type Status string
+
+const (
+	Intact    Status = "intact"
+	Corrupted Status = "corrupted"
+	Invalid   Status = "invalid"
+	Missing   Status = "missing"
+	Extra     Status = "extra"
+)
+
+// Event reports the outcome of verifying one file.
+type Event struct {
+	FilePath string
+	Status   Status
+	Algo     hash.Algo
+	Hash     string
+}
@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/konidev20/refcheck/internal/progress"
 	"github.com/konidev20/refcheck/internal/validator"
 	"github.com/rodaine/table"
 )
 
+// PrintEvent writes a single line reporting ev, for live progress during
+// ProcessFolder/ProcessManifest or --watch mode.
+func PrintEvent(ev progress.Event, w io.Writer) {
+	fmt.Fprintf(w, "[%s] %s\n", ev.Status, ev.FilePath)
+}
+
 func PrintResult(results []*validator.Result, jsonOutput bool, w io.Writer) {
 	if jsonOutput {
 		jsonData, _ := json.MarshalIndent(results, "", "  ")
@@ -28,17 +35,26 @@ func PrintResult(results []*validator.Result, jsonOutput bool, w io.Writer) {
 			tbl.AddRow("Intact Files", result.IntactFiles)
 			tbl.AddRow("Corrupted Files", result.CorruptedFiles)
 			tbl.AddRow("Invalid Files", result.InvalidFiles)
+			if result.MissingFiles > 0 || result.ExtraFiles > 0 {
+				tbl.AddRow("Missing Files", result.MissingFiles)
+				tbl.AddRow("Extra Files", result.ExtraFiles)
+			}
+			if result.CacheHits > 0 || result.CacheMisses > 0 {
+				tbl.AddRow("Cache Hits", result.CacheHits)
+				tbl.AddRow("Cache Misses", result.CacheMisses)
+			}
 			tbl.Print()
 			fmt.Println("")
 			fmt.Println("\nCorrupted Files:")
 			if len(result.CorruptedFileList) > 0 {
-				tbl = table.New("File Path", "Actual Hash")
+				tbl = table.New("File Path", "Algo", "Expected Hash", "Actual Hash")
 				tbl.WithWriter(w)
 				tbl.WithHeaderSeparatorRow('_')
 				tbl.WithPadding(10)
 				for _, file := range result.CorruptedFileList {
-					tbl.AddRow(file.FilePath, file.ActualHash)
+					tbl.AddRow(file.FilePath, file.Algo, file.ExpectedHash, file.ActualHash)
 				}
+				tbl.Print()
 			} else {
 				fmt.Println("None")
 			}
@@ -57,6 +73,42 @@ func PrintResult(results []*validator.Result, jsonOutput bool, w io.Writer) {
 			} else {
 				fmt.Println("None")
 			}
+			if len(result.MissingFileList) > 0 {
+				fmt.Println("")
+				fmt.Println("\nMissing Files:")
+				tbl = table.New("Relative Path")
+				tbl.WithWriter(w)
+				tbl.WithHeaderSeparatorRow('-')
+				tbl.WithPadding(10)
+				for _, file := range result.MissingFileList {
+					tbl.AddRow(file)
+				}
+				tbl.Print()
+			}
+			if len(result.ExtraFileList) > 0 {
+				fmt.Println("")
+				fmt.Println("\nExtra Files:")
+				tbl = table.New("Relative Path")
+				tbl.WithWriter(w)
+				tbl.WithHeaderSeparatorRow('-')
+				tbl.WithPadding(10)
+				for _, file := range result.ExtraFileList {
+					tbl.AddRow(file)
+				}
+				tbl.Print()
+			}
+			if len(result.ActionsPerformed) > 0 {
+				fmt.Println("")
+				fmt.Println("\nActions Performed:")
+				tbl = table.New("File Path", "Applied", "Detail", "Error")
+				tbl.WithWriter(w)
+				tbl.WithHeaderSeparatorRow('-')
+				tbl.WithPadding(10)
+				for _, rec := range result.ActionsPerformed {
+					tbl.AddRow(rec.FilePath, rec.Applied, rec.Detail, rec.Error)
+				}
+				tbl.Print()
+			}
 			fmt.Println("")
 			fmt.Println("-------------------")
 			fmt.Println("")
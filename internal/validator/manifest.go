@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/konidev20/refcheck/internal/filter"
+	"github.com/konidev20/refcheck/internal/hash"
+	"github.com/konidev20/refcheck/internal/progress"
+)
+
+// ValidateManifestEntry verifies filePath's contents against expectedHash, a
+// digest read from an external manifest rather than the filename itself,
+// returning an event describing the outcome.
+//
+// algo pins the hash algorithm to verify against; pass "" to auto-detect the
+// algorithm from the length of expectedHash.
+func ValidateManifestEntry(filePath, expectedHash string, result *Result, algo hash.Algo, co CacheOptions) progress.Event {
+	result.mu.Lock()
+	result.TotalFiles++
+	result.mu.Unlock()
+
+	fileAlgo := algo
+	if fileAlgo == "" {
+		detected, ok := hash.Detect(expectedHash)
+		if !ok {
+			result.mu.Lock()
+			result.InvalidFiles++
+			result.InvalidFileList = append(result.InvalidFileList, filePath)
+			result.mu.Unlock()
+			return progress.Event{FilePath: filePath, Status: progress.Invalid}
+		}
+		fileAlgo = detected
+	} else if !hash.Valid(fileAlgo, expectedHash) {
+		result.mu.Lock()
+		result.InvalidFiles++
+		result.InvalidFileList = append(result.InvalidFileList, filePath)
+		result.mu.Unlock()
+		return progress.Event{FilePath: filePath, Status: progress.Invalid}
+	}
+
+	actualHash, hit, err := digestFileCached(filePath, fileAlgo, co)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return progress.Event{FilePath: filePath, Status: progress.Invalid, Algo: fileAlgo}
+	}
+	recordCacheUse(result, hit, co)
+
+	if actualHash == expectedHash {
+		result.mu.Lock()
+		result.IntactFiles++
+		result.mu.Unlock()
+		return progress.Event{FilePath: filePath, Status: progress.Intact, Algo: fileAlgo, Hash: actualHash}
+	}
+
+	result.mu.Lock()
+	result.CorruptedFiles++
+	result.CorruptedFileList = append(result.CorruptedFileList, CorruptedFile{FilePath: filePath, Algo: fileAlgo, ExpectedHash: expectedHash, ActualHash: actualHash})
+	result.mu.Unlock()
+	return progress.Event{FilePath: filePath, Status: progress.Corrupted, Algo: fileAlgo, Hash: actualHash}
+}
+
+// ProcessManifest walks folderPath and verifies every file f includes
+// against its expected hash in manifest, a map of folder-relative path to
+// expected hex digest (see internal/manifest). Files present on disk but
+// absent from manifest are reported as extras; entries in manifest that the
+// walk does not reach (no such file on disk, or a file f excludes) are
+// reported as missing.
+//
+// Like ProcessFolder, it returns immediately with a channel of per-file
+// events and a wait function; callers must drain events and then call wait
+// to get the final Result and any walk error.
+func ProcessManifest(folderPath string, manifest map[string]string, f *filter.Filter, numWorkers int, algo hash.Algo, co CacheOptions) (<-chan progress.Event, func() (*Result, error)) {
+	result := &Result{FolderPath: folderPath}
+	events := make(chan progress.Event)
+	done := make(chan error, 1)
+
+	var seenMu sync.Mutex
+	seen := make(map[string]bool, len(manifest))
+
+	go func() {
+		defer close(events)
+
+		var wg sync.WaitGroup
+		fileChan := make(chan string)
+
+		for i := 0; i < numWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for filePath := range fileChan {
+					relPath, err := filepath.Rel(folderPath, filePath)
+					if err != nil {
+						relPath = filePath
+					}
+					relPath = filepath.ToSlash(relPath)
+
+					seenMu.Lock()
+					expectedHash, ok := manifest[relPath]
+					if ok {
+						seen[relPath] = true
+					}
+					seenMu.Unlock()
+
+					if !ok {
+						result.mu.Lock()
+						result.ExtraFiles++
+						result.ExtraFileList = append(result.ExtraFileList, relPath)
+						result.mu.Unlock()
+						events <- progress.Event{FilePath: relPath, Status: progress.Extra}
+						continue
+					}
+
+					events <- ValidateManifestEntry(filePath, expectedHash, result, algo, co)
+				}
+			}()
+		}
+
+		err := walkTree(folderPath, f, fileChan)
+
+		close(fileChan)
+		wg.Wait()
+
+		if err == nil {
+			for relPath := range manifest {
+				if !seen[relPath] {
+					result.mu.Lock()
+					result.MissingFiles++
+					result.MissingFileList = append(result.MissingFileList, relPath)
+					result.mu.Unlock()
+					events <- progress.Event{FilePath: relPath, Status: progress.Missing}
+				}
+			}
+		}
+
+		done <- err
+	}()
+
+	wait := func() (*Result, error) {
+		if err := <-done; err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return events, wait
+}
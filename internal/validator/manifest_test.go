@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konidev20/refcheck/internal/filter"
+)
+
+// TestProcessManifest runs a full tree through ProcessManifest end-to-end,
+// exercising the walker together with extra- and missing-file reporting,
+// which only apply at this level and aren't covered by
+// ValidateManifestEntry alone.
+func TestProcessManifest(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile := func(relPath, content string) {
+		path := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	writeFile("intact.bin", "intact content")
+	writeFile("corrupted.bin", "corrupted content")
+	writeFile("extra.bin", "not in the manifest")
+
+	manifest := map[string]string{
+		"intact.bin":    sha256Hex("intact content"),
+		"corrupted.bin": sha256Hex("something else"),
+		"missing.bin":   sha256Hex("never written"),
+	}
+
+	f := filter.New(root, nil)
+
+	events, wait := ProcessManifest(root, manifest, f, 2, "", CacheOptions{})
+	var statuses []string
+	for ev := range events {
+		statuses = append(statuses, string(ev.Status))
+	}
+
+	result, err := wait()
+	if err != nil {
+		t.Fatalf("ProcessManifest returned error: %v", err)
+	}
+
+	if len(statuses) != 4 {
+		t.Fatalf("Expected 4 events (intact, corrupted, extra, missing), got %d: %v", len(statuses), statuses)
+	}
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+	}
+	if result.CorruptedFiles != 1 {
+		t.Errorf("Expected 1 corrupted file, got %d", result.CorruptedFiles)
+	}
+	if result.ExtraFiles != 1 || len(result.ExtraFileList) != 1 || result.ExtraFileList[0] != "extra.bin" {
+		t.Errorf("Expected extra.bin reported as extra, got %+v", result.ExtraFileList)
+	}
+	if result.MissingFiles != 1 || len(result.MissingFileList) != 1 || result.MissingFileList[0] != "missing.bin" {
+		t.Errorf("Expected missing.bin reported as missing, got %+v", result.MissingFileList)
+	}
+}
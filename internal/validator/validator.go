@@ -1,17 +1,26 @@
 package validator
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sync"
+	"time"
+
+	"github.com/konidev20/refcheck/internal/action"
+	"github.com/konidev20/refcheck/internal/cache"
+	"github.com/konidev20/refcheck/internal/filter"
+	"github.com/konidev20/refcheck/internal/hash"
+	"github.com/konidev20/refcheck/internal/progress"
 )
 
 type Result struct {
+	// mu guards every field below against concurrent mutation by the worker
+	// goroutines in ProcessFolder/ProcessManifest.
+	mu sync.Mutex
+
 	FolderPath        string          `json:"folder_path"`
 	TotalFiles        int             `json:"total_files"`
 	IntactFiles       int             `json:"intact_files"`
@@ -19,94 +28,195 @@ type Result struct {
 	CorruptedFileList []CorruptedFile `json:"corrupted_file_list"`
 	InvalidFiles      int             `json:"invalid_files"`
 	InvalidFileList   []string        `json:"invalid_file_list"`
+	MissingFiles      int             `json:"missing_files,omitempty"`
+	MissingFileList   []string        `json:"missing_file_list,omitempty"`
+	ExtraFiles        int             `json:"extra_files,omitempty"`
+	ExtraFileList     []string        `json:"extra_file_list,omitempty"`
+	CacheHits         int             `json:"cache_hits,omitempty"`
+	CacheMisses       int             `json:"cache_misses,omitempty"`
+	ActionsPerformed  []action.Record `json:"actions_performed,omitempty"`
+}
+
+// CacheOptions controls whether ValidateFile and ValidateManifestEntry
+// consult a persistent cache instead of re-hashing a file whose path, size,
+// and modification time haven't changed since it was last verified.
+type CacheOptions struct {
+	Store  *cache.Store
+	MaxAge time.Duration
+	Force  bool
 }
 
 type CorruptedFile struct {
-	FilePath   string `json:"file_path"`
-	ActualHash string `json:"actual_hash"`
+	FilePath     string    `json:"file_path"`
+	Algo         hash.Algo `json:"algo"`
+	ExpectedHash string    `json:"expected_hash"`
+	ActualHash   string    `json:"actual_hash"`
 }
 
-// ValidateFile checks if the file is valid and calculates the SHA256 hash of the file
-func ValidateFile(filePath string, result *Result) {
+// ValidateFile checks if the file is valid and calculates its digest,
+// returning an event describing the outcome.
+//
+// algo pins the hash algorithm to verify against; pass "" to auto-detect the
+// algorithm from the length of the filename, which is assumed to be the
+// expected digest of the file's contents.
+func ValidateFile(filePath string, result *Result, algo hash.Algo, co CacheOptions) progress.Event {
 	expectedHash := filepath.Base(filePath)
+
+	result.mu.Lock()
 	result.TotalFiles++
-	if !isValidSha256(expectedHash) {
+	result.mu.Unlock()
+
+	fileAlgo := algo
+	if fileAlgo == "" {
+		detected, ok := hash.Detect(expectedHash)
+		if !ok {
+			result.mu.Lock()
+			result.InvalidFiles++
+			result.InvalidFileList = append(result.InvalidFileList, filePath)
+			result.mu.Unlock()
+			return progress.Event{FilePath: filePath, Status: progress.Invalid}
+		}
+		fileAlgo = detected
+	} else if !hash.Valid(fileAlgo, expectedHash) {
+		result.mu.Lock()
 		result.InvalidFiles++
 		result.InvalidFileList = append(result.InvalidFileList, filePath)
-		return
+		result.mu.Unlock()
+		return progress.Event{FilePath: filePath, Status: progress.Invalid}
+	}
+
+	actualHash, hit, err := digestFileCached(filePath, fileAlgo, co)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return progress.Event{FilePath: filePath, Status: progress.Invalid, Algo: fileAlgo}
+	}
+	recordCacheUse(result, hit, co)
+
+	if expectedHash == actualHash {
+		result.mu.Lock()
+		result.IntactFiles++
+		result.mu.Unlock()
+		return progress.Event{FilePath: filePath, Status: progress.Intact, Algo: fileAlgo, Hash: actualHash}
 	}
 
+	result.mu.Lock()
+	result.CorruptedFiles++
+	result.CorruptedFileList = append(result.CorruptedFileList, CorruptedFile{FilePath: filePath, Algo: fileAlgo, ExpectedHash: expectedHash, ActualHash: actualHash})
+	result.mu.Unlock()
+	return progress.Event{FilePath: filePath, Status: progress.Corrupted, Algo: fileAlgo, Hash: actualHash}
+}
+
+// digestFile computes the hex digest of filePath's contents using algo.
+func digestFile(filePath string, algo hash.Algo) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		fmt.Printf("Error opening file %s: %v\n", filePath, err)
-		return
+		return "", fmt.Errorf("error opening file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		fmt.Printf("Error calculating SHA256 hash for file %s: %v\n", filePath, err)
-		return
+	h, err := hash.New(algo)
+	if err != nil {
+		return "", fmt.Errorf("error initializing hash for file %s: %w", filePath, err)
+	}
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("error calculating hash for file %s: %w", filePath, err)
 	}
 
-	actualHash := hex.EncodeToString(hash.Sum(nil))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	if expectedHash == actualHash {
-		result.IntactFiles++
-	} else {
-		result.CorruptedFiles++
-		result.CorruptedFileList = append(result.CorruptedFileList, CorruptedFile{FilePath: filePath, ActualHash: actualHash})
+// digestFileCached behaves like digestFile, but consults co.Store first and
+// updates it after a fresh hash, keyed on filePath's size and modification
+// time so an unchanged file is not re-read while its entry is no older than
+// co.MaxAge. It reports whether the digest came from the cache.
+//
+// With co.Force, the cache lookup is skipped but the store is still updated
+// with the freshly computed digest, so a --force run refreshes stale entries
+// instead of leaving the cache behind for the next normal run.
+func digestFileCached(filePath string, algo hash.Algo, co CacheOptions) (string, bool, error) {
+	if co.Store == nil {
+		digest, err := digestFile(filePath, algo)
+		return digest, false, err
 	}
-}
 
-func isValidSha256(hash string) bool {
-	// Check if the hash is 64 characters long
-	if len(hash) != 64 {
-		return false
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("error statting file %s: %w", filePath, err)
 	}
 
-	// Check if the hash contains only hexadecimal digits
-	if !regexp.MustCompile(`^[a-f0-9]+$`).MatchString(hash) {
-		return false
+	if !co.Force {
+		if cached, ok := co.Store.Lookup(filePath, info.Size(), info.ModTime(), algo, co.MaxAge); ok {
+			return cached, true, nil
+		}
 	}
-	return true
+
+	digest, err := digestFile(filePath, algo)
+	if err != nil {
+		return "", false, err
+	}
+	co.Store.Put(filePath, info.Size(), info.ModTime(), algo, digest, time.Now())
+	return digest, false, nil
 }
 
-func ProcessFolder(folderPath string, exclude *regexp.Regexp, numWorkers int) (*Result, error) {
+// recordCacheUse tallies a cache hit or miss in result, but only when a
+// cache was actually in play.
+func recordCacheUse(result *Result, hit bool, co CacheOptions) {
+	if co.Store == nil {
+		return
+	}
+	result.mu.Lock()
+	defer result.mu.Unlock()
+	if hit {
+		result.CacheHits++
+	} else {
+		result.CacheMisses++
+	}
+}
+
+// ProcessFolder walks folderPath and validates every file f includes. algo
+// pins every file to a single hash algorithm; pass "" to auto-detect per
+// file, which lets a single folder mix digests from different ecosystems
+// (e.g. git objects alongside restic keys). co controls whether unchanged
+// files are served from a persistent cache instead of being re-hashed.
+//
+// It returns immediately with a channel of per-file events and a wait
+// function; callers must drain events (the channel is unbuffered) and then
+// call wait to get the final Result and any walk error.
+func ProcessFolder(folderPath string, f *filter.Filter, numWorkers int, algo hash.Algo, co CacheOptions) (<-chan progress.Event, func() (*Result, error)) {
 	result := &Result{FolderPath: folderPath}
+	events := make(chan progress.Event)
+	done := make(chan error, 1)
 
-	var wg sync.WaitGroup
-	fileChan := make(chan string)
+	go func() {
+		defer close(events)
 
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for filePath := range fileChan {
-				if !exclude.MatchString(filePath) {
-					ValidateFile(filePath, result)
-				}
-			}
-		}()
-	}
+		var wg sync.WaitGroup
+		fileChan := make(chan string)
 
-	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			fileChan <- path
+		for i := 0; i < numWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for filePath := range fileChan {
+					events <- ValidateFile(filePath, result, algo, co)
+				}
+			}()
 		}
-		return nil
-	})
 
-	close(fileChan)
-	wg.Wait()
+		err := walkTree(folderPath, f, fileChan)
 
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return nil, err
+		close(fileChan)
+		wg.Wait()
+		done <- err
+	}()
+
+	wait := func() (*Result, error) {
+		if err := <-done; err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return nil, err
+		}
+		return result, nil
 	}
 
-	return result, nil
+	return events, wait
 }
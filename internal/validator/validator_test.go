@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konidev20/refcheck/internal/cache"
+	"github.com/konidev20/refcheck/internal/filter"
+	"github.com/konidev20/refcheck/internal/hash"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestValidateFile(t *testing.T) {
+	tmpDir := os.TempDir()
+	filePath := filepath.Join(tmpDir, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72")
+	tempFile, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.WriteString("test content")
+	if err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	defer tempFile.Close()
+
+	result := &Result{}
+
+	// Test valid file
+	t.Run("Valid File", func(t *testing.T) {
+		ValidateFile(tempFile.Name(), result, "", CacheOptions{})
+		if result.IntactFiles != 1 {
+			t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+		}
+	})
+
+	result = &Result{}
+
+	// Test invalid file name
+	t.Run("Invalid File Name", func(t *testing.T) {
+		ValidateFile("invalidfilename", result, "", CacheOptions{})
+		if result.InvalidFiles != 1 {
+			t.Errorf("Expected 1 invalid file, got %d", result.InvalidFiles)
+		}
+	})
+
+	result = &Result{}
+
+	tempFile.WriteString("modifications")
+
+	// Test corrupted file
+	t.Run("Corrupted File", func(t *testing.T) {
+		ValidateFile(tempFile.Name(), result, "", CacheOptions{})
+		if result.CorruptedFiles != 1 {
+			t.Errorf("Expected 1 corrupted file, got %d", result.CorruptedFiles)
+		}
+		if len(result.CorruptedFileList) != 1 || result.CorruptedFileList[0].Algo != hash.SHA256 {
+			t.Errorf("Expected corrupted file to record algo %q, got %+v", hash.SHA256, result.CorruptedFileList)
+		}
+	})
+
+	result = &Result{}
+
+	// Test explicit algo mismatching the filename length
+	t.Run("Explicit Algo Mismatch", func(t *testing.T) {
+		ValidateFile(tempFile.Name(), result, hash.SHA1, CacheOptions{})
+		if result.InvalidFiles != 1 {
+			t.Errorf("Expected 1 invalid file for sha1-length mismatch, got %d", result.InvalidFiles)
+		}
+	})
+}
+
+func TestValidateFileUsesCache(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("cache.Open returned error: %v", err)
+	}
+	co := CacheOptions{Store: store}
+
+	result := &Result{}
+	ValidateFile(filePath, result, "", co)
+	if result.CacheMisses != 1 || result.CacheHits != 0 {
+		t.Fatalf("Expected a cache miss on first run, got hits=%d misses=%d", result.CacheHits, result.CacheMisses)
+	}
+
+	result = &Result{}
+	ValidateFile(filePath, result, "", co)
+	if result.CacheHits != 1 || result.CacheMisses != 0 {
+		t.Fatalf("Expected a cache hit on second run, got hits=%d misses=%d", result.CacheHits, result.CacheMisses)
+	}
+	if result.IntactFiles != 1 {
+		t.Fatalf("Expected cached result to still report 1 intact file, got %d", result.IntactFiles)
+	}
+}
+
+func TestValidateFileForceRefreshesCache(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("cache.Open returned error: %v", err)
+	}
+	co := CacheOptions{Store: store, Force: true}
+
+	result := &Result{}
+	ValidateFile(filePath, result, "", co)
+	if result.CacheHits != 0 || result.CacheMisses != 1 {
+		t.Fatalf("Expected --force to bypass the lookup and count as a miss, got hits=%d misses=%d", result.CacheHits, result.CacheMisses)
+	}
+
+	// A subsequent non-force run must be served from the cache --force just
+	// populated, not treated as if nothing had ever been cached.
+	co.Force = false
+	result = &Result{}
+	ValidateFile(filePath, result, "", co)
+	if result.CacheHits != 1 || result.CacheMisses != 0 {
+		t.Fatalf("Expected --force run to have refreshed the cache, got hits=%d misses=%d", result.CacheHits, result.CacheMisses)
+	}
+}
+
+// TestProcessFolder runs a full tree through ProcessFolder end-to-end,
+// exercising the walker, excluded-directory SkipDir short-circuiting, and
+// concurrent workers together rather than the single-file helpers in
+// isolation.
+func TestProcessFolder(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile := func(relPath, content string) {
+		path := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	writeFile(sha256Hex("intact content"), "intact content")
+	writeFile(sha256Hex("something else"), "corrupted content")
+	writeFile("not-a-digest", "whatever")
+	// Inside an excluded directory: must never be walked at all, not just
+	// filtered out one file at a time.
+	writeFile(filepath.Join("skip", sha256Hex("skipped")), "skipped")
+
+	f := filter.New(root, []string{"skip/"})
+
+	events, wait := ProcessFolder(root, f, 2, "", CacheOptions{})
+	eventCount := 0
+	for range events {
+		eventCount++
+	}
+
+	result, err := wait()
+	if err != nil {
+		t.Fatalf("ProcessFolder returned error: %v", err)
+	}
+
+	if eventCount != 3 {
+		t.Errorf("Expected 3 events (excluded dir skipped entirely), got %d", eventCount)
+	}
+	if result.TotalFiles != 3 {
+		t.Errorf("Expected 3 total files, got %d", result.TotalFiles)
+	}
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+	}
+	if result.CorruptedFiles != 1 {
+		t.Errorf("Expected 1 corrupted file, got %d", result.CorruptedFiles)
+	}
+	if result.InvalidFiles != 1 {
+		t.Errorf("Expected 1 invalid file, got %d", result.InvalidFiles)
+	}
+}
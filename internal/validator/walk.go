@@ -0,0 +1,38 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/konidev20/refcheck/internal/filter"
+)
+
+// walkTree walks folderPath, feeding fileChan every file f includes. It loads
+// any .refcheckignore found in a directory before deciding whether to
+// descend into it, and skips excluded directories entirely via
+// filepath.SkipDir rather than filtering their contents one file at a time.
+func walkTree(folderPath string, f *filter.Filter, fileChan chan<- string) error {
+	return filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			ignorePath := filepath.Join(path, filter.IgnoreFileName)
+			if _, statErr := os.Stat(ignorePath); statErr == nil {
+				if loadErr := f.LoadIgnoreFile(ignorePath); loadErr != nil {
+					return loadErr
+				}
+			}
+			if path != folderPath && !f.Includes(path, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if f.Includes(path, false) {
+			fileChan <- path
+		}
+		return nil
+	})
+}
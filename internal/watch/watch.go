@@ -0,0 +1,89 @@
+// Package watch keeps refcheck running as a long-lived process, re-verifying
+// individual files as they're created or modified, suitable for CI or a
+// background daemon watching a content-addressed store fed by another
+// process.
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/konidev20/refcheck/internal/filter"
+	"github.com/konidev20/refcheck/internal/hash"
+	"github.com/konidev20/refcheck/internal/progress"
+	"github.com/konidev20/refcheck/internal/validator"
+)
+
+// Run watches folderPath for file creates and writes, re-verifying each
+// changed file against algo (auto-detected per file when "") and co's cache.
+// Events are emitted on events as files are verified; Run blocks until stop
+// is closed or the watcher's channels close. It returns an error only if the
+// watcher cannot be set up; errors the watcher reports while running are
+// printed and do not stop it.
+func Run(folderPath string, f *filter.Filter, algo hash.Algo, co validator.CacheOptions, events chan<- progress.Event, stop <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addRecursive(watcher, folderPath, f); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	result := &validator.Result{FolderPath: folderPath}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("watch: %v\n", err)
+
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if ev.Op&fsnotify.Create != 0 {
+				if info, statErr := os.Stat(ev.Name); statErr == nil && info.IsDir() {
+					if err := addRecursive(watcher, ev.Name, f); err != nil {
+						fmt.Printf("watch: %v\n", err)
+					}
+					continue
+				}
+			}
+
+			if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if !f.Includes(ev.Name, false) {
+				continue
+			}
+
+			events <- validator.ValidateFile(ev.Name, result, algo, co)
+		}
+	}
+}
+
+// addRecursive registers watches for root and every included subdirectory.
+func addRecursive(watcher *fsnotify.Watcher, root string, f *filter.Filter) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && !f.Includes(path, true) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
@@ -1,65 +1,43 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
-	"regexp"
+	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
-	"sync"
-
-	"github.com/rodaine/table"
+	"syscall"
+	"time"
+
+	"github.com/konidev20/refcheck/internal/action"
+	"github.com/konidev20/refcheck/internal/cache"
+	"github.com/konidev20/refcheck/internal/filter"
+	"github.com/konidev20/refcheck/internal/hash"
+	"github.com/konidev20/refcheck/internal/manifest"
+	"github.com/konidev20/refcheck/internal/progress"
+	"github.com/konidev20/refcheck/internal/template"
+	"github.com/konidev20/refcheck/internal/ui"
+	"github.com/konidev20/refcheck/internal/validator"
+	"github.com/konidev20/refcheck/internal/watch"
 	"github.com/spf13/cobra"
 )
 
-type Result struct {
-	FolderPath        string          `json:"folder_path"`
-	TotalFiles        int             `json:"total_files"`
-	IntactFiles       int             `json:"intact_files,omitempty"`
-	CorruptedFiles    int             `json:"corrupted_files,omitempty"`
-	CorruptedFileList []CorruptedFile `json:"corrupted_file_list,omitempty"`
-	InvalidFiles      int             `json:"invalid_files,omitempty"`
-	InvalidFileList   []string        `json:"invalid_file_list,omitempty"`
-}
-
-type CorruptedFile struct {
-	FilePath     string `json:"file_path"`
-	ExpectedHash string `json:"expected_hash"`
-	ActualHash   string `json:"actual_hash"`
-}
-
 type RefCheckOptions struct {
-	Path     string
-	Exclude  []string
-	Workers  int
-	JSON     bool
-	Template []string
-}
-
-type Template struct {
-	Exclude []string
-}
-
-var templates map[string]Template
-
-var resticTemplate Template = Template{
-	Exclude: []string{"config"},
-}
-
-var macOSTemplate Template = Template{
-	Exclude: []string{".DS_Store"},
-}
-
-func init() {
-	templates = map[string]Template{
-		"restic": resticTemplate,
-		"darwin": macOSTemplate,
-	}
+	Path      string
+	Exclude   []string
+	Workers   int
+	JSON      bool
+	Template  []string
+	Algo      string
+	Manifest  string
+	Cache     string
+	MaxAge    string
+	Force     bool
+	Watch     bool
+	OnCorrupt string
+	OnInvalid string
+	Apply     bool
 }
 
 var refCheckOptions RefCheckOptions
@@ -69,8 +47,8 @@ func main() {
 		Use:   "refcheck",
 		Short: "refcheck checks the integrity of files in a directory",
 		Long: `refcheck is a tool for checking the integrity of files in a directory.
-Assuming the file names are the SHA256 hash of the file, it calculates the SHA256 hash of each file and compares it with the file name.
-If the file name matches the hash, the file is intact; otherwise, it is corrupted.
+Assuming the file names are the digest of the file contents, it calculates the digest of each file and compares it with the file name.
+If the file name matches the digest, the file is intact; otherwise, it is corrupted.
 The tool can be used to check the integrity of files in a directory before deploying them to a server.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			runChecker(cmd, refCheckOptions, args)
@@ -80,149 +58,173 @@ The tool can be used to check the integrity of files in a directory before deplo
 	goos := runtime.GOOS
 
 	rootCmd.Flags().StringVarP(&refCheckOptions.Path, "path", "p", ".", "Path to the folder")
-	rootCmd.Flags().StringSliceVarP(&refCheckOptions.Exclude, "exclude", "e", []string{}, "Regular expression pattern for excluding files and folders. Can be specified multiple times.")
+	rootCmd.Flags().StringSliceVarP(&refCheckOptions.Exclude, "exclude", "e", []string{}, "Gitignore-style pattern for excluding files and folders (supports '**', '!' negation, and trailing '/' for directory-only patterns). Can be specified multiple times.")
 	rootCmd.Flags().IntVarP(&refCheckOptions.Workers, "workers", "w", 4, "Number of workers for parallel processing")
 	rootCmd.Flags().BoolVarP(&refCheckOptions.JSON, "json", "j", false, "Print the results in JSON format")
 	rootCmd.Flags().StringSliceVarP(&refCheckOptions.Template, "template", "t", []string{"restic", goos}, "Template to use for excluding files and folders. Can be specified multiple times.")
+	rootCmd.Flags().StringVarP(&refCheckOptions.Algo, "algo", "a", "", fmt.Sprintf("Hash algorithm to verify against (%s). Leave empty to auto-detect per file from filename length.", algoChoices()))
+	rootCmd.Flags().StringVarP(&refCheckOptions.Manifest, "manifest", "m", "", "Path to a manifest file (SHA256SUMS-style or JSON) with expected hashes keyed by relative path, instead of deriving them from filenames.")
+	rootCmd.Flags().StringVarP(&refCheckOptions.Cache, "cache", "c", "", "Path to a verification cache. When set, files whose path, size, and modification time are unchanged since the last run are not re-hashed.")
+	rootCmd.Flags().StringVar(&refCheckOptions.MaxAge, "max-age", "", "Maximum age of a cache entry before it's considered stale and the file is re-hashed (e.g. \"30d\", \"12h\"). Empty means cache entries never expire.")
+	rootCmd.Flags().BoolVar(&refCheckOptions.Force, "force", false, "Ignore the cache and re-hash every file, updating cached entries as usual.")
+	rootCmd.Flags().BoolVar(&refCheckOptions.Watch, "watch", false, "Keep running after the initial check, re-verifying files as they're created or modified. Runs until interrupted.")
+	rootCmd.Flags().StringVar(&refCheckOptions.OnCorrupt, "on-corrupt", "", "Action to run against each corrupted file: \"quarantine:<dir>\", \"delete\", \"rename:<suffix>\", or \"fetch:<url-template>\" (URL template may contain \"{hash}\"). Dry-run unless --apply is set.")
+	rootCmd.Flags().StringVar(&refCheckOptions.OnInvalid, "on-invalid", "", "Action to run against each invalidly-named file. Same syntax as --on-corrupt.")
+	rootCmd.Flags().BoolVar(&refCheckOptions.Apply, "apply", false, "Actually perform --on-corrupt/--on-invalid actions instead of just describing them.")
 
 	rootCmd.Execute()
 }
 
-// collectExcludePatterns compiles a regular expression that matches any of the file or folder patterns
-// specified in the RefCheckOptions. This includes both directly specified exclude patterns and those
-// derived from named templates.
-func collectExcludePatterns(opts RefCheckOptions) *regexp.Regexp {
-	excludePatterns := opts.Exclude
-	for _, template := range opts.Template {
-		excludePatterns = append(excludePatterns, templates[template].Exclude...)
+// parseMaxAge parses a --max-age value, accepting Go duration strings
+// ("12h", "90m") as well as a "<n>d" day shorthand, since "30d" reads more
+// naturally than "720h" for cache retention.
+func parseMaxAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --max-age %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --max-age %q: %w", s, err)
+	}
+	return d, nil
+}
+
+func algoChoices() string {
+	names := make([]string, len(hash.All))
+	for i, a := range hash.All {
+		names[i] = string(a)
 	}
-	combinedPattern := "(" + strings.Join(excludePatterns, ")|(") + ")"
-	return regexp.MustCompile(combinedPattern)
+	return strings.Join(names, ", ")
 }
 
-func runChecker(cmd *cobra.Command, opts RefCheckOptions, _ []string) {
-	folderPath := opts.Path
-	numWorkers := opts.Workers
-	jsonOutput := opts.JSON
-
-	exclude := collectExcludePatterns(opts)
-	result := &Result{FolderPath: folderPath}
-
-	var wg sync.WaitGroup
-	fileChan := make(chan string)
-
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for filePath := range fileChan {
-				if !exclude.MatchString(filePath) {
-					processFile(filePath, result)
-				}
-			}
-		}()
+// buildFilter assembles the gitignore-style patterns specified in opts —
+// directly via --exclude and indirectly via named templates — into a Filter
+// rooted at opts.Path. Additional patterns are picked up from any
+// .refcheckignore files discovered while walking.
+func buildFilter(opts RefCheckOptions) *filter.Filter {
+	patterns := append([]string{}, opts.Exclude...)
+	for _, name := range opts.Template {
+		patterns = append(patterns, template.Templates[name].Exclude...)
 	}
+	return filter.New(opts.Path, patterns)
+}
+
+func runChecker(cmd *cobra.Command, opts RefCheckOptions, _ []string) {
+	exclude := buildFilter(opts)
 
-	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+	var algo hash.Algo
+	if opts.Algo != "" {
+		parsed, err := hash.Parse(opts.Algo)
 		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			fileChan <- path
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
-		return nil
-	})
-
-	close(fileChan)
-	wg.Wait()
+		algo = parsed
+	}
 
+	maxAge, err := parseMaxAge(opts.MaxAge)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
-	if jsonOutput {
-		jsonData, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(jsonData))
-	} else {
-		tbl := table.New("Result", "Value")
-		tbl.WithHeaderSeparatorRow('-')
-		tbl.WithPadding(2)
-		tbl.WithWriter(cmd.OutOrStdout())
-		tbl.AddRow("Total Files", result.TotalFiles)
-		tbl.AddRow("Intact Files", result.IntactFiles)
-		tbl.AddRow("Corrupted Files", result.CorruptedFiles)
-		tbl.AddRow("Invalid Files", result.InvalidFiles)
-		tbl.Print()
-
-		if result.CorruptedFiles > 0 {
-			fmt.Println("\nCorrupted Files:")
-			tbl := table.New("File Path", "Expected Hash", "Actual Hash")
-			tbl.WithWriter(cmd.OutOrStdout())
-			tbl.WithHeaderSeparatorRow('-')
-			tbl.WithPadding(2)
-			for _, file := range result.CorruptedFileList {
-				tbl.AddRow(file.FilePath, file.ExpectedHash, file.ActualHash)
-			}
-			tbl.Print()
+	var co validator.CacheOptions
+	if opts.Cache != "" {
+		store, err := cache.Open(opts.Cache)
+		if err != nil {
+			fmt.Printf("Error opening cache %s: %v\n", opts.Cache, err)
+			return
 		}
-
-		if result.InvalidFiles > 0 {
-			fmt.Println("\nInvalid File Names:")
-			tbl := table.New("File Path")
-			tbl.WithWriter(cmd.OutOrStdout())
-			tbl.WithHeaderSeparatorRow('-')
-			tbl.WithPadding(2)
-			for _, file := range result.InvalidFileList {
-				tbl.AddRow(file)
+		co = validator.CacheOptions{Store: store, MaxAge: maxAge, Force: opts.Force}
+		defer func() {
+			if err := store.Flush(); err != nil {
+				fmt.Printf("Error writing cache %s: %v\n", opts.Cache, err)
 			}
-			tbl.Print()
-		}
+		}()
 	}
-}
 
-// processFile checks if the file is valid and calculates the SHA256 hash of the file
-func processFile(filePath string, result *Result) {
-	expectedHash := filepath.Base(filePath)
-	result.TotalFiles++
-	if !isValidSha256(expectedHash) {
-		result.InvalidFiles++
-		result.InvalidFileList = append(result.InvalidFileList, filePath)
+	onCorrupt, err := action.Parse(opts.OnCorrupt)
+	if err != nil {
+		fmt.Printf("Error: --on-corrupt: %v\n", err)
 		return
 	}
-
-	file, err := os.Open(filePath)
+	onInvalid, err := action.Parse(opts.OnInvalid)
 	if err != nil {
-		fmt.Printf("Error opening file %s: %v\n", filePath, err)
+		fmt.Printf("Error: --on-invalid: %v\n", err)
 		return
 	}
-	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		fmt.Printf("Error calculating SHA256 hash for file %s: %v\n", filePath, err)
-		return
+	var entries map[string]string
+	if opts.Manifest != "" {
+		loaded, err := manifest.Load(opts.Manifest)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		entries = loaded
 	}
 
-	actualHash := hex.EncodeToString(hash.Sum(nil))
-
-	if expectedHash == actualHash {
-		result.IntactFiles++
+	var events <-chan progress.Event
+	var wait func() (*validator.Result, error)
+	if entries != nil {
+		events, wait = validator.ProcessManifest(opts.Path, entries, exclude, opts.Workers, algo, co)
 	} else {
-		result.CorruptedFiles++
-		result.CorruptedFileList = append(result.CorruptedFileList, CorruptedFile{FilePath: filePath, ExpectedHash: expectedHash, ActualHash: actualHash})
+		events, wait = validator.ProcessFolder(opts.Path, exclude, opts.Workers, algo, co)
 	}
-}
 
-func isValidSha256(hash string) bool {
-	// Check if the hash is 64 characters long
-	if len(hash) != 64 {
-		return false
+	out := cmd.OutOrStdout()
+	for ev := range events {
+		if !opts.JSON {
+			ui.PrintEvent(ev, out)
+		}
 	}
 
-	// Check if the hash contains only hexadecimal digits
-	if !regexp.MustCompile(`^[a-f0-9]+$`).MatchString(hash) {
-		return false
+	result, err := wait()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if onCorrupt != nil || onInvalid != nil {
+		corrupted := make([]string, len(result.CorruptedFileList))
+		for i, f := range result.CorruptedFileList {
+			corrupted[i] = f.FilePath
+		}
+		result.ActionsPerformed = action.Run(onCorrupt, onInvalid, corrupted, result.InvalidFileList, opts.Apply)
+	}
+
+	ui.PrintResult([]*validator.Result{result}, opts.JSON, out)
+
+	if !opts.Watch {
+		return
+	}
+
+	stop := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		close(stop)
+	}()
+
+	watchEvents := make(chan progress.Event)
+	go func() {
+		for ev := range watchEvents {
+			ui.PrintEvent(ev, out)
+		}
+	}()
+
+	if err := watch.Run(opts.Path, exclude, algo, co, watchEvents, stop); err != nil {
+		close(watchEvents)
+		fmt.Printf("Error: %v\n", err)
+		return
 	}
-	return true
+	close(watchEvents)
 }